@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/sirupsen/logrus"
+)
+
+// printEffectiveConfig writes cfg to stdout as TOML, for the --print-config flag.
+func printEffectiveConfig(cfg AppConfig) error {
+	return toml.NewEncoder(os.Stdout).Encode(cfg)
+}
+
+// configDirName/configFileName locate gofi's config file at
+// $XDG_CONFIG_HOME/gofi/config.toml, falling back to ~/.config/gofi/config.toml
+const configDirName = "gofi"
+const configFileName = "config.toml"
+
+// Keybindings holds the single-character keys bound to each action, as
+// written in config.toml. Resolve converts them into the runes actually
+// compared against incoming key events.
+type Keybindings struct {
+	Down    string `toml:"down"`
+	Up      string `toml:"up"`
+	Quit    string `toml:"quit"`
+	Sort    string `toml:"sort"`
+	Right   string `toml:"right"`
+	Left    string `toml:"left"`
+	Preview string `toml:"preview"`
+	Select  string `toml:"select"`
+	Delete  string `toml:"delete"`
+	Rename  string `toml:"rename"`
+	Yank    string `toml:"yank"`
+	Cut     string `toml:"cut"`
+	Paste   string `toml:"paste"`
+	Help    string `toml:"help"`
+}
+
+// defaultKeybindings matches gofi's original hardcoded keys.
+func defaultKeybindings() Keybindings {
+	return Keybindings{
+		Down: "j", Up: "k", Quit: "q", Sort: "s", Right: "l", Left: "h",
+		Preview: "p", Select: " ", Delete: "d", Rename: "r", Yank: "y", Cut: "x", Paste: "P", Help: "?",
+	}
+}
+
+// Keymap is Keybindings resolved to the runes compared in SetInputCapture.
+type Keymap struct {
+	Down, Up, Quit, Sort, Right, Left, Preview, Select, Delete, Rename, Yank, Cut, Paste, Help rune
+}
+
+// resolve converts k into a Keymap, erroring if any binding is not exactly
+// one character.
+func (k Keybindings) resolve() (Keymap, error) {
+	var km Keymap
+	var err error
+
+	assign := func(name, value string, dst *rune) {
+		if err != nil {
+			return
+		}
+		runes := []rune(value)
+		if len(runes) != 1 {
+			err = fmt.Errorf("keybindings.%s must be exactly one character, got %q", name, value)
+			return
+		}
+		*dst = runes[0]
+	}
+
+	assign("down", k.Down, &km.Down)
+	assign("up", k.Up, &km.Up)
+	assign("quit", k.Quit, &km.Quit)
+	assign("sort", k.Sort, &km.Sort)
+	assign("right", k.Right, &km.Right)
+	assign("left", k.Left, &km.Left)
+	assign("preview", k.Preview, &km.Preview)
+	assign("select", k.Select, &km.Select)
+	assign("delete", k.Delete, &km.Delete)
+	assign("rename", k.Rename, &km.Rename)
+	assign("yank", k.Yank, &km.Yank)
+	assign("cut", k.Cut, &km.Cut)
+	assign("paste", k.Paste, &km.Paste)
+	assign("help", k.Help, &km.Help)
+
+	return km, err
+}
+
+// Theme holds the tcell color names applied to tview primitives in
+// newApplication.
+type Theme struct {
+	Border    string `toml:"border"`
+	Selection string `toml:"selection"`
+	Header    string `toml:"header"`
+}
+
+func defaultTheme() Theme {
+	return Theme{Border: "white", Selection: "blue", Header: "white"}
+}
+
+// Defaults holds the startup flag defaults config.toml can override. Flags
+// explicitly passed on the command line still win over these.
+type Defaults struct {
+	Watch    bool   `toml:"watch"`
+	MaxDepth int    `toml:"max_depth"`
+	SortMode string `toml:"sort_mode"`
+}
+
+func defaultDefaults() Defaults {
+	return Defaults{Watch: false, MaxDepth: -1, SortMode: "name"}
+}
+
+// AppConfig is gofi's full effective configuration: built-in defaults
+// merged with whatever config.toml overrides.
+type AppConfig struct {
+	Keybindings Keybindings `toml:"keybindings"`
+	Theme       Theme       `toml:"theme"`
+	Defaults    Defaults    `toml:"defaults"`
+}
+
+func defaultAppConfig() AppConfig {
+	return AppConfig{
+		Keybindings: defaultKeybindings(),
+		Theme:       defaultTheme(),
+		Defaults:    defaultDefaults(),
+	}
+}
+
+// configPath resolves the path to gofi's config file.
+func configPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(configHome, configDirName, configFileName), nil
+}
+
+// loadConfig builds gofi's effective AppConfig: built-in defaults, overlaid
+// with config.toml if one is present. A missing config file is not an
+// error; a malformed one or one with an invalid keybinding is logged and
+// falls back to the built-in defaults entirely.
+func loadConfig(logger *logrus.Logger) AppConfig {
+	cfg := defaultAppConfig()
+
+	path, err := configPath()
+	if err != nil {
+		logger.Println("Unable to resolve config path: ", err)
+		return cfg
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return cfg
+	}
+
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		logger.Println("Unable to parse config file, using defaults: ", path, err)
+		return defaultAppConfig()
+	}
+
+	if _, err := cfg.Keybindings.resolve(); err != nil {
+		logger.Println("Invalid config, using defaults: ", err)
+		return defaultAppConfig()
+	}
+
+	return cfg
+}
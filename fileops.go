@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// selectedMark prefixes the list label of items marked with Space
+const selectedMark = "[x] "
+
+// confirmPage is the tview.Pages name for the Yes/No destructive-op modal
+const confirmPage = "confirm"
+
+// renamePage is the tview.Pages name for the rename prompt form
+const renamePage = "rename"
+
+// listLabel renders the text shown in the listView for item, prefixing
+// selectedMark when the item is marked for a multi-select operation.
+func listLabel(app *Application, item Item) string {
+	app.selectedMu.Lock()
+	marked := app.selected[item.Path]
+	app.selectedMu.Unlock()
+
+	if marked {
+		return selectedMark + item.Title
+	}
+	return item.Title
+}
+
+// toggleSelect marks or unmarks the currently highlighted item and advances
+// to the next one, mirroring how file managers let you build up a selection
+// with repeated Space presses.
+func (app *Application) toggleSelect() {
+	app.itemsMu.Lock()
+	if app.selectedItemIndex < 0 || app.selectedItemIndex >= len(app.items) {
+		app.itemsMu.Unlock()
+		return
+	}
+	path := app.items[app.selectedItemIndex].Path
+	app.itemsMu.Unlock()
+
+	app.selectedMu.Lock()
+	app.selected[path] = !app.selected[path]
+	if !app.selected[path] {
+		delete(app.selected, path)
+	}
+	app.selectedMu.Unlock()
+
+	app.refreshListLabels()
+
+	app.itemsMu.Lock()
+	advance := app.selectedItemIndex+1 < len(app.items)
+	app.itemsMu.Unlock()
+	if advance {
+		app.listView.SetCurrentItem(app.selectedItemIndex + 1)
+	}
+}
+
+// refreshListLabels redraws every row's text so selection marks stay in sync
+func (app *Application) refreshListLabels() {
+	app.itemsMu.Lock()
+	items := append([]Item(nil), app.items...)
+	app.itemsMu.Unlock()
+
+	app.application.QueueUpdateDraw(func() {
+		for i, item := range items {
+			app.listView.SetItemText(i, listLabel(app, item), "")
+		}
+	})
+}
+
+// selectedPaths returns the marked paths, or the currently highlighted item's
+// path if nothing is marked, matching how file managers treat an operation
+// invoked with no explicit selection.
+func (app *Application) selectedPaths() []string {
+	app.selectedMu.Lock()
+	if len(app.selected) > 0 {
+		paths := make([]string, 0, len(app.selected))
+		for path := range app.selected {
+			paths = append(paths, path)
+		}
+		app.selectedMu.Unlock()
+		return paths
+	}
+	app.selectedMu.Unlock()
+
+	app.itemsMu.Lock()
+	defer app.itemsMu.Unlock()
+	if app.selectedItemIndex >= 0 && app.selectedItemIndex < len(app.items) {
+		return []string{app.items[app.selectedItemIndex].Path}
+	}
+
+	return nil
+}
+
+// reportProgress surfaces a one-line status message in the footer, e.g.
+// while a long-running file operation is in flight.
+func (app *Application) reportProgress(msg string) {
+	app.application.QueueUpdateDraw(func() {
+		app.footerView.SetText(msg)
+	})
+}
+
+// confirmDelete asks for Yes/No confirmation (gdu's askBeforeDelete modal
+// pattern) before deleting the current selection.
+func (app *Application) confirmDelete() {
+	paths := app.selectedPaths()
+	if len(paths) == 0 {
+		return
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Delete %d item(s)?", len(paths))).
+		AddButtons([]string{"Yes", "No"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			app.pages.RemovePage(confirmPage)
+			app.application.SetFocus(app.listView)
+			if buttonLabel == "Yes" {
+				go app.deletePaths(paths)
+			}
+		})
+
+	app.pages.AddPage(confirmPage, modal, true, true)
+	app.application.SetFocus(modal)
+}
+
+// confirmOverwrite asks for Yes/No confirmation before an operation would
+// clobber something already at dst, the same way confirmDelete gates a
+// destructive delete behind a prompt.
+func (app *Application) confirmOverwrite(text string, onConfirm func()) {
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Yes", "No"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			app.pages.RemovePage(confirmPage)
+			app.application.SetFocus(app.listView)
+			if buttonLabel == "Yes" {
+				onConfirm()
+			}
+		})
+
+	app.pages.AddPage(confirmPage, modal, true, true)
+	app.application.SetFocus(modal)
+}
+
+// deletePaths runs Operations.Delete for each path in turn (confirmDelete
+// starts it in its own goroutine), reporting progress to the footer and emitting itemRemoved as each one
+// completes so the listView drops it live.
+func (app *Application) deletePaths(paths []string) {
+	for i, path := range paths {
+		app.reportProgress(fmt.Sprintf("Deleting %d/%d: %s", i+1, len(paths), path))
+
+		if err := app.operations.Delete(path); err != nil {
+			app.logger.Println("Unable to delete: ", path, err)
+			continue
+		}
+
+		app.selectedMu.Lock()
+		delete(app.selected, path)
+		app.selectedMu.Unlock()
+
+		app.itemRemoved <- Item{Path: path}
+	}
+
+	app.reportProgress(fmt.Sprintf("Deleted %d item(s)", len(paths)))
+}
+
+// promptRename opens a single-field form prefilled with the current
+// selection's name and renames it to whatever is submitted.
+func (app *Application) promptRename() {
+	app.itemsMu.Lock()
+	if app.selectedItemIndex < 0 || app.selectedItemIndex >= len(app.items) {
+		app.itemsMu.Unlock()
+		return
+	}
+	item := app.items[app.selectedItemIndex]
+	app.itemsMu.Unlock()
+
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle(" Rename (Esc to cancel) ")
+	form.AddInputField("New name", item.Title, 40, nil, nil)
+
+	cancel := func() {
+		app.pages.RemovePage(renamePage)
+		app.application.SetFocus(app.listView)
+	}
+
+	form.AddButton("Rename", func() {
+		newName := form.GetFormItem(0).(*tview.InputField).GetText()
+		newPath := filepath.Join(filepath.Dir(item.Path), newName)
+		cancel()
+
+		rename := func() { app.renameTo(item.Path, newPath) }
+
+		if _, err := os.Stat(newPath); err == nil {
+			app.confirmOverwrite(fmt.Sprintf("%s already exists. Overwrite?", newName), rename)
+			return
+		}
+
+		rename()
+	})
+	form.AddButton("Cancel", cancel)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			cancel()
+			return nil
+		}
+		return event
+	})
+
+	app.pages.AddPage(renamePage, centeredModal(form, 60, 7), true, true)
+	app.application.SetFocus(form)
+}
+
+// renameTo performs the actual Operations.Rename and emits the matching
+// itemRemoved/itemAdded events, used by promptRename once any overwrite has
+// been confirmed (or there was nothing to overwrite).
+func (app *Application) renameTo(oldPath, newPath string) {
+	if err := app.operations.Rename(oldPath, newPath); err != nil {
+		app.logger.Println("Unable to rename: ", oldPath, err)
+		return
+	}
+
+	app.itemRemoved <- Item{Path: oldPath}
+	if built, err := buildItem(newPath); err == nil {
+		app.itemAdded <- *built
+	}
+}
+
+// yank copies the current selection's paths into the in-memory clipboard,
+// to be copied into the current directory by a later paste.
+func (app *Application) yank() {
+	paths := app.selectedPaths()
+	if len(paths) == 0 {
+		return
+	}
+
+	app.clipboard = paths
+	app.clipboardCut = false
+	app.reportProgress(fmt.Sprintf("Yanked %d item(s)", len(paths)))
+}
+
+// cut marks the current selection's paths to be moved into the current
+// directory by a later paste, instead of yank's copy.
+func (app *Application) cut() {
+	paths := app.selectedPaths()
+	if len(paths) == 0 {
+		return
+	}
+
+	app.clipboard = paths
+	app.clipboardCut = true
+	app.reportProgress(fmt.Sprintf("Cut %d item(s)", len(paths)))
+}
+
+// paste copies (or, after cut, moves) every path in the clipboard into the
+// current directory, confirming first if any of them would overwrite
+// something already there.
+func (app *Application) paste() {
+	if len(app.clipboard) == 0 {
+		return
+	}
+
+	paths := app.clipboard
+	move := app.clipboardCut
+	dir := app.currentDirectory()
+
+	var conflicts int
+	for _, src := range paths {
+		if _, err := os.Stat(filepath.Join(dir, filepath.Base(src))); err == nil {
+			conflicts++
+		}
+	}
+
+	if conflicts == 0 {
+		go app.pastePaths(paths, dir, move)
+		return
+	}
+
+	app.confirmOverwrite(
+		fmt.Sprintf("%d item(s) already exist in this directory. Overwrite?", conflicts),
+		func() { go app.pastePaths(paths, dir, move) },
+	)
+}
+
+// pastePaths is paste's worker: it copies (or moves, when move is true)
+// every path in paths into dir, reporting progress to the footer and
+// emitting itemAdded (and, for a move, itemRemoved for the source) as each
+// one completes. Run in its own goroutine once any overwrite has been
+// confirmed. A completed move clears the clipboard, since the moved paths
+// no longer exist to paste again.
+func (app *Application) pastePaths(paths []string, dir string, move bool) {
+	verb := "Pasting"
+	if move {
+		verb = "Moving"
+	}
+
+	for i, src := range paths {
+		dst := filepath.Join(dir, filepath.Base(src))
+		app.reportProgress(fmt.Sprintf("%s %d/%d: %s", verb, i+1, len(paths), dst))
+
+		var err error
+		if move {
+			err = app.operations.Move(src, dst)
+		} else {
+			err = app.operations.Copy(src, dst)
+		}
+		if err != nil {
+			app.logger.Println("Unable to ", strings.ToLower(verb), ": ", src, " -> ", dst, err)
+			continue
+		}
+
+		if move {
+			app.itemRemoved <- Item{Path: src}
+		}
+		if built, err := buildItem(dst); err == nil {
+			app.itemAdded <- *built
+		}
+	}
+
+	done := "Pasted"
+	if move {
+		done = "Moved"
+		app.clipboard = nil
+		app.clipboardCut = false
+	}
+	app.reportProgress(fmt.Sprintf("%s %d item(s)", done, len(paths)))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logFilePath is where gofi's structured logs are written
+const logFilePath = "logs.txt"
+
+// defaultLogLevel is used when GOFI_LOG_LEVEL is unset or invalid
+const defaultLogLevel = logrus.InfoLevel
+
+// logBufferCapacity bounds how many recent log lines the in-app log viewer can show
+const logBufferCapacity = 500
+
+// LogBuffer keeps the most recent log lines in memory so the log viewer modal
+// can tail the current session without re-reading logs.txt from disk.
+type LogBuffer struct {
+	mu    sync.Mutex
+	lines []string
+	cap   int
+}
+
+// NewLogBuffer creates a LogBuffer holding at most capacity lines
+func NewLogBuffer(capacity int) *LogBuffer {
+	return &LogBuffer{cap: capacity}
+}
+
+func (b *LogBuffer) add(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lines = append(b.lines, line)
+	if len(b.lines) > b.cap {
+		b.lines = b.lines[len(b.lines)-b.cap:]
+	}
+}
+
+// Lines returns a snapshot of the buffered log lines, oldest first
+func (b *LogBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lines := make([]string, len(b.lines))
+	copy(lines, b.lines)
+	return lines
+}
+
+// bufferHook is a logrus.Hook that appends every formatted entry to a LogBuffer
+type bufferHook struct {
+	buffer *LogBuffer
+}
+
+func (h *bufferHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *bufferHook) Fire(entry *logrus.Entry) error {
+	line, err := entry.String()
+	if err != nil {
+		return err
+	}
+
+	h.buffer.add(strings.TrimRight(line, "\n"))
+	return nil
+}
+
+// newLogger builds the structured logger used throughout gofi: JSON to
+// logFilePath by default, a human-readable formatter when debug is true,
+// level taken from GOFI_LOG_LEVEL (defaulting to info), and a hook feeding
+// buffer so the in-app log viewer (bound to '?') can tail the current
+// session.
+func newLogger(debug bool, buffer *LogBuffer) *logrus.Logger {
+	file, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(file)
+
+	if debug {
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	} else {
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	}
+
+	level := defaultLogLevel
+	if raw := os.Getenv("GOFI_LOG_LEVEL"); raw != "" {
+		if parsed, err := logrus.ParseLevel(raw); err != nil {
+			logger.Warnf("Invalid GOFI_LOG_LEVEL %q, falling back to %s", raw, defaultLogLevel)
+		} else {
+			level = parsed
+		}
+	}
+	logger.SetLevel(level)
+
+	logger.AddHook(&bufferHook{buffer: buffer})
+
+	return logger
+}
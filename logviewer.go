@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// logViewerPage is the tview.Pages name for the log viewer modal
+const logViewerPage = "logs"
+
+// showLogViewer opens a modal tailing the current session's buffered log
+// entries, following gdu's pages/modal overlay pattern. Press Escape to close.
+func (app *Application) showLogViewer() {
+	view := tview.NewTextView()
+	view.SetBorder(true).SetTitle(" Logs (Esc to close) ")
+	view.SetText(strings.Join(app.logBuffer.Lines(), "\n"))
+	view.ScrollToEnd()
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			app.pages.RemovePage(logViewerPage)
+			app.application.SetFocus(app.listView)
+			return nil
+		}
+		return event
+	})
+
+	app.pages.AddPage(logViewerPage, centeredModal(view, 80, 20), true, true)
+	app.application.SetFocus(view)
+}
+
+// centeredModal wraps p in nested Flex primitives so it renders as a
+// fixed-size box centered over whatever page is behind it.
+func centeredModal(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(
+			tview.NewFlex().SetDirection(tview.FlexRow).
+				AddItem(nil, 0, 1, false).
+				AddItem(p, height, 0, true).
+				AddItem(nil, 0, 1, false),
+			width, 0, true,
+		).
+		AddItem(nil, 0, 1, false)
+}
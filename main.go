@@ -1,29 +1,35 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"io/ioutil"
-	"log"
 	"os"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/tools/godoc/util"
 	"golang.org/x/tools/godoc/vfs"
 )
 
-//Keycodes
-const (
-	J rune = 106
-	K      = 107
-	Q      = 113
-)
-
 type Item struct {
+	Path        string
 	Title       string
 	Description string
 	Footer      string
+	Size        int64
+	Children    int
+	IsDir       bool
+
+	// generation ties an Item to the scan that produced it, so a scan
+	// cancelled by navigating elsewhere can be told apart from the one
+	// currently populating the list. Zero for items that do not come from a
+	// directory scan (fsnotify, rename, paste), which are never stale.
+	generation int64
 }
 
 /**
@@ -40,31 +46,95 @@ type Application struct {
 	footerView         *tview.TextView
 	descriptionView    *tview.TextArea
 	items              []Item
+	itemsMu            sync.Mutex // guards items against the item-receiving goroutine started in start()
 	selectedItemIndex  int
 	itemAdded          chan Item      // new item is added via this channel
+	itemRemoved        chan Item      // item removed from disk is sent via this channel
+	itemUpdated        chan Item      // item modified on disk is sent via this channel
 	stopReceiveNewItem chan bool      // signal other goroutines to quit
+	stopWatcher        chan bool      // signal the fsnotify watcher goroutine to quit
 	waitgroup          sync.WaitGroup // wait until all goroutines complete
+	sortMode           SortMode
+	scanner            *Scanner
+	currentDir         string
+	currentDirMu       sync.Mutex // guards currentDir against the fsnotify watcher goroutine
+	scanCancel         context.CancelFunc
+	scanGeneration     int64 // bumped by navigate; drops scanner items left over from a cancelled scan
+	previewView        *tview.TextView
+	previewVisible     bool
+	previewCancel      context.CancelFunc
+	sidebarFlex        *tview.Flex
+	pages              *tview.Pages
+	logger             *logrus.Logger
+	logBuffer          *LogBuffer
+	operations         *Operations
+	selected           map[string]bool // paths marked in the list, toggled with the select key
+	selectedMu         sync.Mutex      // guards selected against the goroutine deletePaths runs in
+	clipboard          []string        // paths yanked with yank or cut with cut, pasted with paste
+	clipboardCut       bool            // true when clipboard came from cut: paste should move, not copy
+	keymap             Keymap
 }
 
-func newApplication() *Application {
+func newApplication(cfg AppConfig, logger *logrus.Logger, logBuffer *LogBuffer) *Application {
+	keymap, err := cfg.Keybindings.resolve()
+	if err != nil {
+		logger.Println("Invalid keybindings, falling back to defaults: ", err)
+		keymap, _ = defaultKeybindings().resolve()
+	}
+
 	app := Application{
 		application:        tview.NewApplication(),
 		listView:           tview.NewList(),
 		headerView:         tview.NewTextView(),
 		footerView:         tview.NewTextView(),
 		descriptionView:    tview.NewTextArea(),
+		previewView:        tview.NewTextView().SetDynamicColors(true),
+		previewVisible:     true,
 		itemAdded:          make(chan Item, 100),
+		itemRemoved:        make(chan Item, 100),
+		itemUpdated:        make(chan Item, 100),
 		stopReceiveNewItem: make(chan bool, 10),
+		stopWatcher:        make(chan bool, 10),
+		logger:             logger,
+		logBuffer:          logBuffer,
+		selected:           make(map[string]bool),
+		keymap:             keymap,
 	}
 
-	// Redraw the other views when list selected item is changed
+	borderColor := tcell.GetColor(cfg.Theme.Border)
+	app.listView.SetBorderColor(borderColor)
+	app.headerView.SetBorderColor(borderColor)
+	app.descriptionView.SetBorderColor(borderColor)
+	app.previewView.SetBorderColor(borderColor)
+	app.footerView.SetBorderColor(borderColor)
+	app.listView.SetSelectedBackgroundColor(tcell.GetColor(cfg.Theme.Selection))
+	app.headerView.SetTextColor(tcell.GetColor(cfg.Theme.Header))
+
+	app.sidebarFlex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(app.headerView, 3, 0, false).
+		AddItem(app.descriptionView, 8, 0, false).
+		AddItem(app.previewView, 0, 1, false).
+		AddItem(app.footerView, 3, 0, false)
+
+	// Redraw the other views when list selected item is changed. headerView is
+	// left alone here: it always shows the current directory breadcrumb, set
+	// by navigate.
 	app.listView.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
 		app.selectedItemIndex = index
+
+		app.itemsMu.Lock()
+		if index < 0 || index >= len(app.items) {
+			app.itemsMu.Unlock()
+			return
+		}
 		item := app.items[index]
-		log.Println("Selected item ", item.Title)
-		app.headerView.SetText(item.Title)
+		total := len(app.items)
+		app.itemsMu.Unlock()
+
+		app.logger.Println("Selected item ", item.Title)
 		app.descriptionView.SetText(item.Description, false)
-		app.footerView.SetText(fmt.Sprintf("%d/%d: %s", index+1, len(app.items), item.Footer))
+		app.footerView.SetText(fmt.Sprintf("%d/%d: %s", index+1, total, item.Footer))
+		app.showPreview(item)
 	})
 
 	app.listView.SetMouseCapture(
@@ -79,20 +149,56 @@ func newApplication() *Application {
 	app.application.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		// Before quiting, send quit signal to stop the coroutine and stop receiving new items
 		if event.Key() == tcell.KeyCtrlC {
-			log.Println("Pressed Ctrl+C, stopping app...")
+			app.logger.Println("Pressed Ctrl+C, stopping app...")
 			app.stopReceiveNewItem <- true
+			app.stopWatcher <- true
 		}
 
-		// Use j and k to navigate through the list
+		// Keys below are remappable via config.toml's [keybindings] table;
+		// app.keymap holds the resolved, effective bindings.
 		if app.application.GetFocus() == app.listView {
-			if event.Rune() == J {
+			if event.Rune() == app.keymap.Down {
 				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
-			} else if event.Rune() == K {
+			} else if event.Rune() == app.keymap.Up {
 				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
-			} else if event.Rune() == Q {
-				log.Println("Pressed Q, stopping app...")
+			} else if event.Rune() == app.keymap.Quit {
+				app.logger.Println("Pressed quit key, stopping app...")
 				app.stopReceiveNewItem <- true
+				app.stopWatcher <- true
 				return tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModCtrl)
+			} else if event.Rune() == app.keymap.Sort {
+				app.cycleSortMode()
+				return nil
+			} else if event.Key() == tcell.KeyEnter || event.Rune() == app.keymap.Right {
+				app.enterSelected()
+				return nil
+			} else if event.Rune() == app.keymap.Left || event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2 {
+				app.ascend()
+				return nil
+			} else if event.Rune() == app.keymap.Preview {
+				app.togglePreview()
+				return nil
+			} else if event.Rune() == app.keymap.Select {
+				app.toggleSelect()
+				return nil
+			} else if event.Rune() == app.keymap.Delete {
+				app.confirmDelete()
+				return nil
+			} else if event.Rune() == app.keymap.Rename {
+				app.promptRename()
+				return nil
+			} else if event.Rune() == app.keymap.Yank {
+				app.yank()
+				return nil
+			} else if event.Rune() == app.keymap.Cut {
+				app.cut()
+				return nil
+			} else if event.Rune() == app.keymap.Paste {
+				app.paste()
+				return nil
+			} else if event.Rune() == app.keymap.Help {
+				app.showLogViewer()
+				return nil
 			}
 		}
 
@@ -108,11 +214,12 @@ func newApplication() *Application {
 * This new goroutine will stop when receiving a signal in the app.quit channel
  */
 func (app *Application) start() {
-	log.Println("Starting application...")
+	app.logger.Println("Starting application...")
 	app.listView.SetBorder(true)
 	app.descriptionView.SetBorder(true)
 	app.headerView.SetBorder(true)
 	app.footerView.SetBorder(true)
+	app.previewView.SetBorder(true)
 
 	app.waitgroup.Add(1)
 	// a new goroutine to receive new items from app.itemAdded channel
@@ -127,124 +234,216 @@ func (app *Application) start() {
 					break NEW_ITEM_LOOP
 				}
 			case newItem := <-app.itemAdded:
-				log.Println("Receiving newly added item: ", newItem.Title)
+				if !app.isCurrentGeneration(newItem.generation) {
+					app.logger.Println("Dropping stale item from a cancelled scan: ", newItem.Title)
+					continue
+				}
+				app.logger.Println("Receiving newly added item: ", newItem.Title)
 				app.addItem(&newItem)
+			case removedItem := <-app.itemRemoved:
+				app.logger.Println("Receiving removed item: ", removedItem.Path)
+				app.removeItem(removedItem.Path)
+			case updatedItem := <-app.itemUpdated:
+				if !app.isCurrentGeneration(updatedItem.generation) {
+					app.logger.Println("Dropping stale item from a cancelled scan: ", updatedItem.Path)
+					continue
+				}
+				app.logger.Println("Receiving updated item: ", updatedItem.Path)
+				app.updateItem(&updatedItem)
 			default:
 				continue
 			}
 		}
 
-		log.Println("No longer receive new items")
+		app.logger.Println("No longer receive new items")
 	}()
 
 	flex := tview.NewFlex().
 		AddItem(app.listView, 30, 0, true).
-		AddItem(
-			tview.NewFlex().SetDirection(tview.FlexRow).
-				AddItem(app.headerView, 3, 0, false).
-				AddItem(app.descriptionView, 0, 1, false).
-				AddItem(app.footerView, 3, 0, false),
-			0, 7, false,
-		)
-
-		// start tview application
-	if err := app.application.SetRoot(flex, true).EnableMouse(true).Run(); err != nil {
+		AddItem(app.sidebarFlex, 0, 7, false)
+
+	app.pages = tview.NewPages().AddPage("main", flex, true, true)
+
+	// start tview application
+	if err := app.application.SetRoot(app.pages, true).EnableMouse(true).Run(); err != nil {
 		panic(err)
 	}
 
-	log.Println("Waiting until no longer receiving new item...")
+	app.logger.Println("Waiting until no longer receiving new item...")
 	app.waitgroup.Wait()
-	log.Println("Stopped")
+	app.logger.Println("Stopped")
+}
+
+// isCurrentGeneration reports whether generation belongs to the scan
+// currently populating the list. A zero generation (fsnotify, rename, paste)
+// is never stale; anything else is compared against app.scanGeneration so
+// results from a scan cancelled by a later navigate get dropped instead of
+// leaking into whatever directory is shown now.
+func (app *Application) isCurrentGeneration(generation int64) bool {
+	return generation == 0 || generation == atomic.LoadInt64(&app.scanGeneration)
 }
 
 func (app *Application) addItem(item *Item) {
+	app.itemsMu.Lock()
 	app.items = append(app.items, *item)
+	total := len(app.items)
+	app.itemsMu.Unlock()
+
 	app.application.QueueUpdateDraw(func() {
-		app.listView.AddItem(item.Title, "", 0, nil)
+		app.listView.AddItem(listLabel(app, *item), "", 0, nil)
 		// Redraw footer to because the total item is changed
-		app.footerView.SetText(fmt.Sprintf("%d/%d: %s", app.selectedItemIndex+1, len(app.items), item.Footer))
+		app.footerView.SetText(fmt.Sprintf("%d/%d: %s", app.selectedItemIndex+1, total, item.Footer))
 	})
 }
 
-/**
-* Read the directory and create one Item for each file/subdir found
-* The created items will be sent to itemAddedChannel
-*/
-func scanDirectory(itemAddedChannel chan<- Item) {
-	var path string
-	if len(os.Args) == 1 {
-		log.Println("No path, using current working directory")
-		currentDir, err := os.Getwd()
+// indexOfItem returns the index of the item with the given path, or -1 if not
+// found. Callers must hold app.itemsMu.
+func (app *Application) indexOfItem(path string) int {
+	for i, item := range app.items {
+		if item.Path == path {
+			return i
+		}
+	}
+	return -1
+}
 
-		if err != nil {
-			log.Println("Unable to get current directory. ", err)
-			return
+// removeItem drops the item at path from both app.items and the listView
+func (app *Application) removeItem(path string) {
+	app.itemsMu.Lock()
+	index := app.indexOfItem(path)
+	if index == -1 {
+		app.itemsMu.Unlock()
+		app.logger.Println("Unable to remove item, not found: ", path)
+		return
+	}
+	app.items = append(app.items[:index], app.items[index+1:]...)
+	total := len(app.items)
+	app.itemsMu.Unlock()
+
+	app.application.QueueUpdateDraw(func() {
+		app.listView.RemoveItem(index)
+		app.footerView.SetText(fmt.Sprintf("%d/%d", app.selectedItemIndex+1, total))
+	})
+}
+
+// updateItem refreshes an already-known item in place, matched by path
+func (app *Application) updateItem(item *Item) {
+	app.itemsMu.Lock()
+	index := app.indexOfItem(item.Path)
+	if index == -1 {
+		app.itemsMu.Unlock()
+		app.logger.Println("Unable to update item, not found: ", item.Path)
+		return
+	}
+	app.items[index] = *item
+	app.itemsMu.Unlock()
+
+	app.application.QueueUpdateDraw(func() {
+		app.listView.SetItemText(index, listLabel(app, *item), "")
+		if index == app.selectedItemIndex {
+			app.descriptionView.SetText(item.Description, false)
 		}
+	})
+}
 
-		log.Println("Current directory: ", currentDir)
-		path = currentDir
-	} else {
-		path = os.Args[1]
-		log.Println("Path: ", path)
+// itemFromInfo builds the Item shown in the list for fullPath, reusing an
+// already-obtained os.FileInfo rather than calling os.Stat again.
+func itemFromInfo(fullPath string, info os.FileInfo) Item {
+	item := Item{
+		Path:   fullPath,
+		Title:  filepath.Base(fullPath),
+		Footer: fullPath,
+		Size:   info.Size(),
+		IsDir:  info.IsDir(),
 	}
+	item.Description = renderDescription(item, info.Mode())
+
+	return item
+}
 
-	files, err := ioutil.ReadDir(path)
+// renderDescription renders the text shown in the descriptionView for item
+func renderDescription(item Item, mode os.FileMode) string {
+	isDirLabel := "No"
+	if item.IsDir {
+		isDirLabel = "Yes"
+	}
 
+	fileFormat := "Bin"
+	if !item.IsDir && util.IsTextFile(vfs.OS("/"), item.Path) {
+		fileFormat = "Text"
+	}
+
+	return fmt.Sprintf(
+		"File size: %d\nParent dir: %s\nFile mode: %s\nDirectory: %s\nFile format: %s",
+		item.Size,
+		filepath.Dir(item.Path),
+		mode,
+		isDirLabel,
+		fileFormat,
+	)
+}
+
+// buildItem stats the file at fullPath and builds the Item shown in the list
+// for it. Used where only a path is available, e.g. fsnotify events.
+func buildItem(fullPath string) (*Item, error) {
+	info, err := os.Stat(fullPath)
 	if err != nil {
-		log.Println("Unable to read dir: ", path, err)
-		return
+		return nil, err
 	}
 
-	for _, file := range files {
-		log.Println("Adding ", file.Name())
-		fullPath := fmt.Sprintf("%s/%s", path, file.Name())
-		fileStat, err := os.Stat(fullPath)
+	item := itemFromInfo(fullPath, info)
+	return &item, nil
+}
 
-		if err != nil {
-			log.Printf("Unable to get stat of '%s'. %s", file.Name(), err)
-			continue
+func main() {
+	bootstrapLogger := logrus.New()
+	cfg := loadConfig(bootstrapLogger)
+
+	watch := flag.Bool("watch", cfg.Defaults.Watch, "watch the target directory for changes and update the list live")
+	recursive := flag.Bool("r", false, "when used with --watch, also watch subdirectories recursively")
+	maxDepth := flag.Int("max-depth", cfg.Defaults.MaxDepth, "maximum number of directory levels to scan into (-1 means unlimited)")
+	debug := flag.Bool("debug", false, "use a human-readable log formatter instead of JSON")
+	trash := flag.Bool("trash", false, "move deleted items to the trash directory instead of removing them")
+	printConfigFlag := flag.Bool("print-config", false, "print the effective merged configuration and exit")
+	flag.Parse()
+
+	if *printConfigFlag {
+		if err := printEffectiveConfig(cfg); err != nil {
+			bootstrapLogger.Fatal("Unable to print config. ", err)
 		}
+		return
+	}
 
-		isDir := "No"
-		if fileStat.IsDir() {
-			isDir = "Yes"
-		}
+	logBuffer := NewLogBuffer(logBufferCapacity)
+	logger := newLogger(*debug, logBuffer)
 
-		fileFormat := "Bin"
-		if util.IsTextFile(vfs.OS("/"), fullPath) {
-			fileFormat = "Text"
-		}
+	var path string
+	if flag.NArg() == 0 {
+		logger.Println("No path, using current working directory")
+		currentDir, err := os.Getwd()
 
-		newItem := Item{
-			Title: file.Name(),
-			Description: fmt.Sprintf(
-				"File size: %d\nParent dir: %s\nFile mode: %s\nDirectory: %s\nFile format: %s",
-				fileStat.Size(),
-				path,
-				fileStat.Mode(),
-				isDir,
-				fileFormat,
-			),
-			Footer: fullPath,
+		if err != nil {
+			logger.Fatal("Unable to get current directory. ", err)
 		}
 
-		itemAddedChannel <- newItem
+		logger.Println("Current directory: ", currentDir)
+		path = currentDir
+	} else {
+		path = flag.Arg(0)
+		logger.Println("Path: ", path)
 	}
-}
 
-func main() {
-	initLogger()
-	application := newApplication()
-	go scanDirectory(application.itemAdded)
-	application.start()
-}
+	application := newApplication(cfg, logger, logBuffer)
+	application.sortMode = parseSortMode(cfg.Defaults.SortMode)
+	application.scanner = NewScanner(*maxDepth, application.itemAdded, application.itemUpdated, logger)
+	application.operations = NewOperations(*trash, logger)
+	application.navigate(path)
 
-func initLogger() {
-	file, err := os.OpenFile("logs.txt", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-	if err != nil {
-		log.Fatal(err)
+	if *watch {
+		application.waitgroup.Add(1)
+		go watchDirectory(path, *recursive, application)
 	}
 
-	log.SetOutput(file)
+	application.start()
 }
 
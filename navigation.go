@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// navigate switches the app to list path: any in-flight scan is cancelled,
+// the list/items are reset, the header breadcrumb is updated and a fresh
+// scan of path is started.
+//
+// Cancelling ctx only asks the previous scan's goroutines to stop; it does
+// not retroactively un-send items already queued on itemAdded/itemUpdated
+// moments earlier. So every scan is tagged with a generation, bumped here,
+// and NEW_ITEM_LOOP (main.go) drops anything that doesn't match the current
+// one instead of appending it to the directory we just switched to.
+func (app *Application) navigate(path string) {
+	if app.scanCancel != nil {
+		app.scanCancel()
+	}
+
+	generation := atomic.AddInt64(&app.scanGeneration, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	app.scanCancel = cancel
+
+	app.currentDirMu.Lock()
+	app.currentDir = path
+	app.currentDirMu.Unlock()
+
+	app.selectedItemIndex = 0
+
+	app.itemsMu.Lock()
+	app.items = nil
+	app.itemsMu.Unlock()
+
+	app.application.QueueUpdateDraw(func() {
+		app.listView.Clear()
+		app.headerView.SetText(breadcrumb(path))
+	})
+
+	if parent := filepath.Dir(path); parent != path {
+		app.addItem(&Item{Path: parent, Title: "..", Footer: parent, IsDir: true, generation: generation})
+	}
+
+	go app.scanner.Scan(ctx, path, generation)
+}
+
+// enterSelected navigates into the selected item if it is a directory
+func (app *Application) enterSelected() {
+	app.itemsMu.Lock()
+	if app.selectedItemIndex < 0 || app.selectedItemIndex >= len(app.items) {
+		app.itemsMu.Unlock()
+		return
+	}
+	item := app.items[app.selectedItemIndex]
+	app.itemsMu.Unlock()
+
+	if !item.IsDir {
+		return
+	}
+
+	app.navigate(item.Path)
+}
+
+// ascend navigates to the parent of the current directory, if any
+func (app *Application) ascend() {
+	current := app.currentDirectory()
+	parent := filepath.Dir(current)
+	if parent == current {
+		return
+	}
+
+	app.navigate(parent)
+}
+
+// currentDirectory returns the directory currently shown in the list.
+func (app *Application) currentDirectory() string {
+	app.currentDirMu.Lock()
+	defer app.currentDirMu.Unlock()
+	return app.currentDir
+}
+
+// breadcrumb renders path as a readable breadcrumb for the headerView
+func breadcrumb(path string) string {
+	return strings.ReplaceAll(path, string(filepath.Separator), " > ")
+}
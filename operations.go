@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Operations performs the filesystem mutations behind gofi's file-ops
+// keybindings (delete/rename/copy/move). When trash is true, Delete moves
+// files into the XDG trash directory instead of removing them outright.
+type Operations struct {
+	trash  bool
+	logger *logrus.Logger
+}
+
+// NewOperations builds an Operations. When trash is true, Delete sends files
+// to the user's trash directory instead of removing them.
+func NewOperations(trash bool, logger *logrus.Logger) *Operations {
+	return &Operations{trash: trash, logger: logger}
+}
+
+// Delete removes path, or moves it into the trash directory when trash mode
+// is enabled.
+func (o *Operations) Delete(path string) error {
+	if o.trash {
+		return o.moveToTrash(path)
+	}
+
+	o.logger.Println("Removing: ", path)
+	return os.RemoveAll(path)
+}
+
+// Rename moves oldPath to newPath. Like os.Rename, it replaces a file already
+// at newPath.
+func (o *Operations) Rename(oldPath, newPath string) error {
+	o.logger.Println("Renaming: ", oldPath, " -> ", newPath)
+	return os.Rename(oldPath, newPath)
+}
+
+// Move moves src to dst, replacing a file already at dst. It is the same
+// operation as Rename, exposed separately since a paste after cut is
+// conceptually a move of previously cut paths rather than a rename of the
+// selection.
+func (o *Operations) Move(src, dst string) error {
+	o.logger.Println("Moving: ", src, " -> ", dst)
+	return os.Rename(src, dst)
+}
+
+// Copy copies src to dst. If src is a directory, its contents are copied
+// recursively.
+func (o *Operations) Copy(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		o.logger.Println("Copying file: ", src, " -> ", dst)
+		return copyFile(src, dst, info.Mode())
+	}
+
+	o.logger.Println("Copying directory: ", src, " -> ", dst)
+	if err := os.MkdirAll(dst, info.Mode()); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := o.Copy(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyFile copies a single regular file, preserving mode.
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
+
+// trashDir resolves the directory gofi moves deleted files into in --trash
+// mode, following the XDG trash convention: $XDG_DATA_HOME/Trash, falling
+// back to $HOME/.local/share/Trash.
+func trashDir() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+
+	return filepath.Join(dataHome, "Trash"), nil
+}
+
+// moveToTrash moves path into trashDir, disambiguating the destination name
+// with a timestamp suffix if something with the same name is already there.
+// os.Rename fails with EXDEV when path and the trash directory are on
+// different filesystems (e.g. /tmp, a second disk), so that case falls back
+// to a copy-then-remove, the same way real trash implementations do.
+func (o *Operations) moveToTrash(path string) error {
+	dir, err := trashDir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	dst := filepath.Join(dir, filepath.Base(path))
+	if _, err := os.Stat(dst); err == nil {
+		dst = filepath.Join(dir, filepath.Base(path)+"."+time.Now().Format("20060102150405"))
+	}
+
+	o.logger.Println("Trashing: ", path, " -> ", dst)
+	err = os.Rename(path, dst)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	o.logger.Println("Trash directory is on a different filesystem, copying instead: ", path, " -> ", dst)
+	if err := o.Copy(path, dst); err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// testOperations builds an Operations with a logger set to PanicLevel, so
+// test runs stay quiet.
+func testOperations(trash bool) *Operations {
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+	logger.SetLevel(logrus.PanicLevel)
+	return NewOperations(trash, logger)
+}
+
+func TestOperationsDelete(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := testOperations(false).Delete(path); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be gone, stat err = %v", path, err)
+	}
+}
+
+func TestOperationsDeleteTrash(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	path := filepath.Join(src, "file.txt")
+	if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := testOperations(true).Delete(path); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be gone, stat err = %v", path, err)
+	}
+
+	trashed := filepath.Join(dir, "Trash", "file.txt")
+	if _, err := os.Stat(trashed); err != nil {
+		t.Fatalf("expected %s in trash: %v", trashed, err)
+	}
+}
+
+func TestOperationsRename(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.txt")
+	newPath := filepath.Join(dir, "new.txt")
+	if err := os.WriteFile(oldPath, []byte("data"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := testOperations(false).Rename(oldPath, newPath); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be gone, stat err = %v", oldPath, err)
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Fatalf("expected %s to exist: %v", newPath, err)
+	}
+}
+
+func TestOperationsMove(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	dst := filepath.Join(dir, "dst.txt")
+	if err := os.WriteFile(src, []byte("data"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := testOperations(false).Move(src, dst); err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be gone, stat err = %v", src, err)
+	}
+	if _, err := os.Stat(dst); err != nil {
+		t.Fatalf("expected %s to exist: %v", dst, err)
+	}
+}
+
+func TestOperationsCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	dst := filepath.Join(dir, "dst.txt")
+	if err := os.WriteFile(src, []byte("data"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := testOperations(false).Copy(src, dst); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+
+	if _, err := os.Stat(src); err != nil {
+		t.Fatalf("expected source %s to remain: %v", src, err)
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(got) != "data" {
+		t.Fatalf("copied content = %q, want %q", got, "data")
+	}
+}
+
+func TestOperationsCopyDirectory(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(filepath.Join(src, "nested"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "nested", "b.txt"), []byte("b"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	dst := filepath.Join(dir, "dst")
+	if err := testOperations(false).Copy(src, dst); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dst, "nested", "b.txt"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(got) != "b" {
+		t.Fatalf("copied nested content = %q, want %q", got, "b")
+	}
+}
+
+func TestOperationsDeleteTrashCollision(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+
+	trashDir := filepath.Join(dir, "Trash")
+	if err := os.MkdirAll(trashDir, 0700); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(trashDir, "file.txt"), []byte("old"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	path := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(path, []byte("new"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := testOperations(true).Delete(path); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	entries, err := os.ReadDir(trashDir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries in trash after a name collision, got %d", len(entries))
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/rivo/tview"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/tools/godoc/util"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// maxPreviewBytes caps how much of a file is loaded into the preview pane
+const maxPreviewBytes = 64 * 1024
+
+// chromaStyleName is the chroma style used to render syntax-highlighted previews
+const chromaStyleName = "monokai"
+
+// togglePreview shows or hides the preview pane, reloading the current
+// selection's preview when it becomes visible again
+func (app *Application) togglePreview() {
+	app.previewVisible = !app.previewVisible
+
+	proportion := 0
+	if app.previewVisible {
+		proportion = 1
+	}
+
+	app.application.QueueUpdateDraw(func() {
+		app.sidebarFlex.ResizeItem(app.previewView, 0, proportion)
+	})
+
+	app.itemsMu.Lock()
+	inRange := app.selectedItemIndex >= 0 && app.selectedItemIndex < len(app.items)
+	var item Item
+	if inRange {
+		item = app.items[app.selectedItemIndex]
+	}
+	app.itemsMu.Unlock()
+
+	if app.previewVisible && inRange {
+		app.showPreview(item)
+	}
+}
+
+// showPreview cancels any in-flight preview load and starts loading item's
+// preview off the UI goroutine. It is a no-op while the preview pane is
+// hidden or the item is a directory.
+func (app *Application) showPreview(item Item) {
+	if app.previewCancel != nil {
+		app.previewCancel()
+	}
+
+	if !app.previewVisible || item.IsDir {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	app.previewCancel = cancel
+
+	go loadPreview(ctx, app, item)
+}
+
+// loadPreview reads up to maxPreviewBytes of item's file, renders it as
+// syntax-highlighted text (text files) or a hex dump (binary files), and
+// writes the result into app.previewView. It bails out without touching the
+// UI if ctx is cancelled, e.g. because the selection moved on again.
+func loadPreview(ctx context.Context, app *Application, item Item) {
+	file, err := os.Open(item.Path)
+	if err != nil {
+		app.logger.Println("Unable to open for preview: ", item.Path, err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxPreviewBytes))
+	if err != nil {
+		app.logger.Println("Unable to read for preview: ", item.Path, err)
+		return
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	var rendered string
+	if util.IsTextFile(vfs.OS("/"), item.Path) {
+		rendered = highlightText(item.Path, data, app.logger)
+	} else {
+		rendered = tview.Escape(hexDump(data))
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	app.application.QueueUpdateDraw(func() {
+		if ctx.Err() != nil {
+			return
+		}
+		app.previewView.SetText(rendered)
+		app.previewView.ScrollToBeginning()
+	})
+}
+
+// highlightText tokenises data using the lexer matching path and renders it
+// as tview color-tagged text using the chromaStyleName style
+func highlightText(path string, data []byte, logger *logrus.Logger) string {
+	lexer := lexers.Match(path)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	style := styles.Get(chromaStyleName)
+	if style == nil {
+		style = styles.Fallback
+	}
+
+	iterator, err := lexer.Tokenise(nil, string(data))
+	if err != nil {
+		logger.Println("Unable to tokenise preview: ", path, err)
+		return tview.Escape(string(data))
+	}
+
+	var sb strings.Builder
+	for _, token := range iterator.Tokens() {
+		escaped := tview.Escape(token.Value)
+		entry := style.Get(token.Type)
+		if entry.Colour.IsSet() {
+			sb.WriteString(fmt.Sprintf("[%s]%s[-]", entry.Colour.String(), escaped))
+		} else {
+			sb.WriteString(escaped)
+		}
+	}
+
+	return sb.String()
+}
+
+// hexDump renders data as a classic 16-bytes-per-line hex+ASCII dump
+func hexDump(data []byte) string {
+	var sb strings.Builder
+
+	for offset := 0; offset < len(data); offset += 16 {
+		end := offset + 16
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := data[offset:end]
+
+		sb.WriteString(fmt.Sprintf("%08x  ", offset))
+		for i := 0; i < 16; i++ {
+			if i < len(chunk) {
+				sb.WriteString(fmt.Sprintf("%02x ", chunk[i]))
+			} else {
+				sb.WriteString("   ")
+			}
+			if i == 7 {
+				sb.WriteString(" ")
+			}
+		}
+
+		sb.WriteString(" |")
+		for _, b := range chunk {
+			if b >= 32 && b < 127 {
+				sb.WriteByte(b)
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteString("|\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SortMode controls the order Items are presented in the listView
+type SortMode int
+
+const (
+	SortByName SortMode = iota
+	SortBySizeDesc
+	SortByModTime
+	sortModeCount
+)
+
+func (m SortMode) String() string {
+	switch m {
+	case SortByName:
+		return "name"
+	case SortBySizeDesc:
+		return "size"
+	case SortByModTime:
+		return "mtime"
+	default:
+		return "unknown"
+	}
+}
+
+// parseSortMode maps a config.toml "sort_mode" value to a SortMode,
+// defaulting to SortByName for an empty or unrecognised value.
+func parseSortMode(name string) SortMode {
+	switch name {
+	case "size":
+		return SortBySizeDesc
+	case "mtime":
+		return SortByModTime
+	default:
+		return SortByName
+	}
+}
+
+// cycleSortMode advances to the next SortMode and re-sorts the list in place
+func (app *Application) cycleSortMode() {
+	app.sortMode = (app.sortMode + 1) % sortModeCount
+	app.logger.Println("Sort mode: ", app.sortMode)
+	app.applySort()
+}
+
+// applySort reorders app.items according to app.sortMode and rebuilds the
+// listView to match. Sorting takes app.itemsMu because a scan may still be
+// appending to app.items while the sort key is pressed.
+func (app *Application) applySort() {
+	app.itemsMu.Lock()
+	switch app.sortMode {
+	case SortByName:
+		sort.SliceStable(app.items, func(i, j int) bool {
+			return app.items[i].Title < app.items[j].Title
+		})
+	case SortBySizeDesc:
+		sort.SliceStable(app.items, func(i, j int) bool {
+			return app.items[i].Size > app.items[j].Size
+		})
+	case SortByModTime:
+		sort.SliceStable(app.items, func(i, j int) bool {
+			iInfo, iErr := os.Stat(app.items[i].Path)
+			jInfo, jErr := os.Stat(app.items[j].Path)
+			if iErr != nil || jErr != nil {
+				return false
+			}
+			return iInfo.ModTime().After(jInfo.ModTime())
+		})
+	}
+	items := append([]Item(nil), app.items...)
+	app.itemsMu.Unlock()
+
+	app.application.QueueUpdateDraw(func() {
+		app.listView.Clear()
+		for _, item := range items {
+			app.listView.AddItem(listLabel(app, item), "", 0, nil)
+		}
+	})
+}
+
+// Scanner walks a directory tree concurrently with a bounded worker pool,
+// streaming an Item for every entry it finds and keeping directory sizes
+// updated as their subtree finishes scanning.
+type Scanner struct {
+	maxDepth   int
+	numWorkers int
+	logger     *logrus.Logger
+
+	itemAdded   chan<- Item
+	itemUpdated chan<- Item
+}
+
+// NewScanner builds a Scanner that reports discovered/updated items on the
+// given channels. maxDepth < 0 means scan with no depth limit.
+func NewScanner(maxDepth int, itemAdded, itemUpdated chan<- Item, logger *logrus.Logger) *Scanner {
+	numWorkers := runtime.NumCPU()
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+
+	return &Scanner{
+		maxDepth:    maxDepth,
+		numWorkers:  numWorkers,
+		logger:      logger,
+		itemAdded:   itemAdded,
+		itemUpdated: itemUpdated,
+	}
+}
+
+// Scan walks the immediate children of path (and, recursively, their
+// subtrees) fanning traversal out across s.numWorkers workers, and blocks
+// until the whole (depth-limited) tree has been scanned or ctx is cancelled.
+// path itself is not reported as an Item, only what it contains. generation
+// is stamped onto every Item this scan emits, so a caller that starts a
+// later scan can tell its items apart from ones left over from this one.
+func (s *Scanner) Scan(ctx context.Context, path string, generation int64) {
+	sem := make(chan struct{}, s.numWorkers)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	s.scanDir(ctx, path, 0, generation, sem, &wg, nil, false)
+	wg.Wait()
+}
+
+// scanDir scans one directory, reporting an Item for every entry. Regular
+// files are stat-ed once via DirEntry.Info rather than os.Stat again.
+// Subdirectories are recursed into on a worker goroutine while the pool has
+// room, and inline (blocking the caller) when it is full. Once a
+// subdirectory finishes, onChildSize (if set) is called with its aggregated
+// size so every ancestor's total keeps growing as scanning progresses.
+// emitSelf is false only for the scan root, since the root directory itself
+// is never shown as a row in its own listing.
+// generation is stamped onto every Item emitted, see Scan.
+func (s *Scanner) scanDir(ctx context.Context, dirPath string, depth int, generation int64, sem chan struct{}, wg *sync.WaitGroup, onChildSize func(int64), emitSelf bool) {
+	defer wg.Done()
+
+	var total int64
+	defer func() {
+		if onChildSize != nil {
+			onChildSize(atomic.LoadInt64(&total))
+		}
+	}()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	dirInfo, err := os.Stat(dirPath)
+	if err != nil {
+		s.logger.Println("Unable to stat dir: ", dirPath, err)
+		return
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		s.logger.Println("Unable to read dir: ", dirPath, err)
+		return
+	}
+
+	dirItem := itemFromInfo(dirPath, dirInfo)
+	dirItem.Size = 0
+	dirItem.Children = len(entries)
+	dirItem.Description = renderDescription(dirItem, dirInfo.Mode())
+	dirItem.generation = generation
+
+	if emitSelf {
+		s.itemAdded <- dirItem
+	}
+
+	// report is called concurrently by every child subdirectory's own
+	// goroutine as it finishes, each passing its own delta. It must not
+	// mutate the dirItem captured above: two siblings finishing around the
+	// same time would race on the same struct. Instead each call builds and
+	// sends its own independent Item off the current running total.
+	report := func(delta int64) {
+		newTotal := atomic.AddInt64(&total, delta)
+		if !emitSelf {
+			return
+		}
+
+		updated := dirItem
+		updated.Size = newTotal
+		updated.Description = renderDescription(updated, dirInfo.Mode())
+		s.itemUpdated <- updated
+	}
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		fullPath := filepath.Join(dirPath, entry.Name())
+
+		info, err := entry.Info()
+		if err != nil {
+			s.logger.Println("Unable to stat: ", fullPath, err)
+			continue
+		}
+
+		if !entry.IsDir() {
+			item := itemFromInfo(fullPath, info)
+			item.generation = generation
+			s.itemAdded <- item
+			report(info.Size())
+			continue
+		}
+
+		if s.maxDepth >= 0 && depth >= s.maxDepth {
+			childItem := itemFromInfo(fullPath, info)
+			childItem.Children = -1 // unknown, descent stopped by --max-depth
+			childItem.generation = generation
+			s.itemAdded <- childItem
+			continue
+		}
+
+		wg.Add(1)
+		select {
+		case sem <- struct{}{}:
+			go func(childPath string, childDepth int) {
+				defer func() { <-sem }()
+				s.scanDir(ctx, childPath, childDepth, generation, sem, wg, report, true)
+			}(fullPath, depth+1)
+		default:
+			// worker pool is full, scan inline to bound goroutine growth
+			s.scanDir(ctx, fullPath, depth+1, generation, sem, wg, report, true)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// drainScan runs s.Scan(ctx, path, generation) to completion, collecting
+// every Item it emits on itemAdded/itemUpdated so the scan never blocks on
+// an unread channel send.
+func drainScan(t *testing.T, s *Scanner, ctx context.Context, path string, generation int64) ([]Item, []Item) {
+	t.Helper()
+
+	added := make(chan Item)
+	updated := make(chan Item)
+	s.itemAdded = added
+	s.itemUpdated = updated
+
+	var gotAdded, gotUpdated []Item
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for added != nil || updated != nil {
+			select {
+			case item, ok := <-added:
+				if !ok {
+					added = nil
+					continue
+				}
+				gotAdded = append(gotAdded, item)
+			case item, ok := <-updated:
+				if !ok {
+					updated = nil
+					continue
+				}
+				gotUpdated = append(gotUpdated, item)
+			}
+		}
+	}()
+
+	s.Scan(ctx, path, generation)
+	close(added)
+	close(updated)
+	<-done
+
+	return gotAdded, gotUpdated
+}
+
+func TestScannerScanAggregatesDirectorySize(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), make([]byte, 10), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	nested := filepath.Join(dir, "nested")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(nested, "b.txt"), make([]byte, 20), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	s := NewScanner(-1, nil, nil, logger)
+
+	added, updated := drainScan(t, s, context.Background(), dir, 1)
+
+	var nestedFinal Item
+	for _, item := range updated {
+		if item.Path == nested {
+			nestedFinal = item
+		}
+	}
+	if nestedFinal.Size != 20 {
+		t.Fatalf("nested dir size = %d, want 20", nestedFinal.Size)
+	}
+
+	for _, item := range append(append([]Item(nil), added...), updated...) {
+		if item.generation != 1 {
+			t.Fatalf("item %s has generation %d, want 1", item.Path, item.generation)
+		}
+	}
+}
+
+func TestScannerScanCancellation(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 10; i++ {
+		sub := filepath.Join(dir, string(rune('a'+i)))
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	s := NewScanner(-1, nil, nil, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	added, updated := drainScan(t, s, ctx, dir, 1)
+
+	if len(added) != 0 || len(updated) != 0 {
+		t.Fatalf("expected no items from a pre-cancelled scan, got %d added, %d updated", len(added), len(updated))
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// watchDirectory watches path (and, when recursive is true, every subdirectory
+// beneath it) for changes and translates fsnotify events into itemAdded,
+// itemRemoved and itemUpdated messages on app's channels. It stops when a
+// value is received on app.stopWatcher and always signals app.waitgroup.Done
+// before returning.
+func watchDirectory(path string, recursive bool, app *Application) {
+	defer app.waitgroup.Done()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		app.logger.Println("Unable to start watcher: ", err)
+		return
+	}
+	defer watcher.Close()
+
+	if err := addWatch(watcher, path, recursive, app.logger); err != nil {
+		app.logger.Println("Unable to watch: ", path, err)
+		return
+	}
+
+	for {
+		select {
+		case stop := <-app.stopWatcher:
+			if stop {
+				app.logger.Println("Stopping directory watcher...")
+				return
+			}
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			handleWatchEvent(watcher, recursive, event, app)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			app.logger.Println("Watcher error: ", err)
+		default:
+			continue
+		}
+	}
+}
+
+// addWatch adds path to watcher, and when recursive is true walks down and
+// adds a watch for every subdirectory too, mirroring the recursive-watch
+// idiom from the fsnotify examples.
+func addWatch(watcher *fsnotify.Watcher, path string, recursive bool, logger *logrus.Logger) error {
+	if !recursive {
+		return watcher.Add(path)
+	}
+
+	return filepath.Walk(path, func(walkedPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			logger.Println("Unable to walk: ", walkedPath, err)
+			return nil
+		}
+
+		if info.IsDir() {
+			logger.Println("Watching directory: ", walkedPath)
+			if err := watcher.Add(walkedPath); err != nil {
+				logger.Println("Unable to watch: ", walkedPath, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// handleWatchEvent turns a single fsnotify event into the matching
+// itemAdded/itemRemoved/itemUpdated message, re-adding watches on new
+// directories since inotify drops them on RENAME/REMOVE. With -r the watch
+// covers the whole subtree below the CLI-supplied path, which the listing no
+// longer does once navigate has moved elsewhere, so events for anything
+// outside app.currentDirectory() are dropped rather than forwarded into
+// whatever directory happens to be displayed now.
+func handleWatchEvent(watcher *fsnotify.Watcher, recursive bool, event fsnotify.Event, app *Application) {
+	inCurrentDir := filepath.Dir(event.Name) == app.currentDirectory()
+
+	switch {
+	case event.Op&fsnotify.Create == fsnotify.Create:
+		app.logger.Println("Created: ", event.Name)
+
+		if inCurrentDir {
+			item, err := buildItem(event.Name)
+			if err != nil {
+				app.logger.Println("Unable to build item for: ", event.Name, err)
+			} else {
+				app.itemAdded <- *item
+			}
+		}
+
+		// inotify does not watch new subdirectories on its own, so add one
+		// explicitly (and for every subdirectory it arrived with) to keep
+		// recursive watches covering the whole tree
+		if recursive {
+			if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+				if err := addWatch(watcher, event.Name, true, app.logger); err != nil {
+					app.logger.Println("Unable to watch new directory: ", event.Name, err)
+				}
+			}
+		}
+
+	case event.Op&fsnotify.Write == fsnotify.Write:
+		app.logger.Println("Modified: ", event.Name)
+		if !inCurrentDir {
+			return
+		}
+
+		item, err := buildItem(event.Name)
+		if err != nil {
+			app.logger.Println("Unable to build item for: ", event.Name, err)
+			return
+		}
+
+		app.itemUpdated <- *item
+
+	case event.Op&fsnotify.Remove == fsnotify.Remove || event.Op&fsnotify.Rename == fsnotify.Rename:
+		app.logger.Println("Removed/renamed: ", event.Name)
+		if inCurrentDir {
+			// inotify silently drops the watch for a removed/renamed path, so
+			// there is nothing left to clean up on the watcher itself
+			app.itemRemoved <- Item{Path: event.Name}
+		}
+	}
+}